@@ -0,0 +1,49 @@
+package architecture
+
+// EventKind identifies why an Event was emitted. SetState derives one of
+// EventReleased, EventBuried, EventKicked or the EventStateChange
+// catch-all from the transition itself; EventTTRExpired is never derived
+// automatically (a RESERVED->READY transition looks identical whether a
+// client released the job or its TTR expired) and must be requested
+// explicitly via SetStateTTRExpired.
+type EventKind int
+
+const (
+	EventStateChange EventKind = iota // DELAYED->READY, READY->RESERVED
+	EventReleased                     // RESERVED->READY or RESERVED->DELAYED
+	EventBuried                       // RESERVED->BURIED
+	EventKicked                       // BURIED->READY
+	EventTTRExpired                   // RESERVED->READY via TTR expiry, not a client release
+	EventDeleted
+	EventAwaitingRegistered
+	EventAwaitingFulfilled
+)
+
+// Event describes a single job lifecycle occurrence: a state transition,
+// a deletion, or an awaiting client being registered or fulfilled.
+type Event struct {
+	Tube      string
+	Kind      EventKind
+	JobID     string
+	Pri       int64
+	PrevState State // j.state before the transition; unset for non-state-change kinds
+	State     State
+	Timestamp int64
+}
+
+// Sink receives Events as they occur. Publisher is set by whatever
+// subsystem wants to observe job lifecycle activity (see package stream);
+// it is nil until one is installed, in which case publishing is a no-op.
+type Sink interface {
+	Publish(Event)
+}
+
+// Publisher is the installed Sink, if any. It is not safe to change
+// concurrently with job state transitions; install it once at startup.
+var Publisher Sink
+
+func publish(e Event) {
+	if Publisher != nil {
+		Publisher.Publish(e)
+	}
+}
@@ -17,6 +17,7 @@ const ( // iota is reset to 0
 
 type Job struct {
 	id             string
+	Tube           string // name of the tube the job belongs to
 	Pri            int64
 	Delay          int64 // time set as delay in seconds
 	StartedDelayAt int64 // timestamp of when it was set to delayed
@@ -27,11 +28,16 @@ type Job struct {
 
 	// states
 	state State
+
+	// spill-to-disk
+	spilled  bool
+	spillRef SpillRef
 }
 
-func NewJob(id string, pri, delay, ttr, bytes int64, data string) *Job {
+func NewJob(tube, id string, pri, delay, ttr, bytes int64, data string) *Job {
 	j := new(Job)
 	j.id = id
+	j.Tube = tube
 	j.Pri = pri
 	j.Delay = delay
 	j.TTR = ttr
@@ -47,28 +53,51 @@ func NewJob(id string, pri, delay, ttr, bytes int64, data string) *Job {
 	return j
 }
 
-/**
-
-   put with delay               release with delay
-  ----------------> [DELAYED] <------------.
-                        |                   |
-                        | (time passes)     |
-                        |                   |
-   put                  v     reserve       |       delete
-  -----------------> [READY] ---------> [RESERVED] --------> *poof*
-                       ^  ^                |  |
-                       |   \  release      |  |
-                       |    `-------------'   |
-                       |                      |
-                       | kick                 |
-                       |                      |
-                       |       bury           |
-                    [BURIED] <---------------'
-                       |
-                       |  delete
-                        `--------> *poof*
+/*
+*
+
+	 put with delay               release with delay
+	----------------> [DELAYED] <------------.
+	                      |                   |
+	                      | (time passes)     |
+	                      |                   |
+	 put                  v     reserve       |       delete
+	-----------------> [READY] ---------> [RESERVED] --------> *poof*
+	                     ^  ^                |  |
+	                     |   \  release      |  |
+	                     |    `-------------'   |
+	                     |                      |
+	                     | kick                 |
+	                     |                      |
+	                     |       bury           |
+	                  [BURIED] <---------------'
+	                     |
+	                     |  delete
+	                      `--------> *poof*
 */
+// autoKind tells setState to derive the Event's Kind from the transition
+// itself rather than use a caller-supplied one.
+const autoKind EventKind = -1
+
 func (j *Job) SetState(state State) error {
+	return j.setState(state, autoKind)
+}
+
+// SetStateTTRExpired transitions a RESERVED job back to READY exactly
+// like SetState(READY) would, except the emitted Event is tagged
+// EventTTRExpired instead of EventReleased, so notify targets configured
+// to fire only on TTR expiry can tell it apart from an ordinary client
+// release. Callers that expire reservations (e.g. the store package's
+// leader sweep) should call this instead of SetState.
+func (j *Job) SetStateTTRExpired() error {
+	if j.state != RESERVED {
+		return errors.New("Invalid state transition to READY")
+	}
+	return j.setState(READY, EventTTRExpired)
+}
+
+func (j *Job) setState(state State, kind EventKind) error {
+	prevState := j.state
 	switch state {
 	case READY:
 		if j.state == RESERVED || j.state == DELAYED || j.state == BURIED {
@@ -97,13 +126,56 @@ func (j *Job) SetState(state State) error {
 			return errors.New("Invalid state transition to BURIED")
 		}
 	}
+	if kind == autoKind {
+		kind = deriveKind(prevState, j.state)
+	}
+	publish(Event{
+		Tube:      j.Tube,
+		Kind:      kind,
+		JobID:     j.id,
+		Pri:       j.Pri,
+		PrevState: prevState,
+		State:     j.state,
+		Timestamp: time.Now().Unix(),
+	})
 	return nil
 }
 
+// deriveKind classifies an unambiguous transition. Transitions that look
+// the same regardless of cause (RESERVED->READY for a release or a TTR
+// expiry) default to EventReleased; callers that know better, like
+// SetStateTTRExpired, override it.
+func deriveKind(prev, next State) EventKind {
+	switch {
+	case prev == RESERVED && next == BURIED:
+		return EventBuried
+	case prev == BURIED && next == READY:
+		return EventKicked
+	case prev == RESERVED && (next == READY || next == DELAYED):
+		return EventReleased
+	default:
+		return EventStateChange
+	}
+}
+
 func (j *Job) State() State {
 	return j.state
 }
 
+// Delete removes the job from circulation. It does not change j.state since
+// there is no DELETED state to transition into; callers are expected to drop
+// the job from its queue immediately after calling this.
+func (j *Job) Delete() {
+	publish(Event{
+		Tube:      j.Tube,
+		Kind:      EventDeleted,
+		JobID:     j.id,
+		Pri:       j.Pri,
+		State:     j.state,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
 // Return proper key according to the present job state
 func (j *Job) Key() int64 {
 	switch j.state {
@@ -123,17 +195,24 @@ func (j *Job) Id() string {
 	return j.id
 }
 
-
 // AwaitingClient stores an awaiting client send channel for a tube
 type AwaitingClient struct {
 	id          string
+	Tube        string
 	SendChannel chan Job
 }
 
-func NewAwaitingClient(sendChannel chan Job) *AwaitingClient {
+func NewAwaitingClient(tube string, sendChannel chan Job) *AwaitingClient {
 	a := new(AwaitingClient)
 	a.id = uuid.NewV1().String()
+	a.Tube = tube
 	a.SendChannel = sendChannel
+	publish(Event{
+		Tube:      a.Tube,
+		Kind:      EventAwaitingRegistered,
+		JobID:     a.id,
+		Timestamp: time.Now().Unix(),
+	})
 	return a
 }
 
@@ -143,4 +222,16 @@ func (w *AwaitingClient) Key() int64 {
 
 func (w *AwaitingClient) Id() string {
 	return w.id
-}
\ No newline at end of file
+}
+
+// Fulfill notifies subscribers that the awaiting client has been handed a
+// job, and should be called once SendChannel has received it.
+func (w *AwaitingClient) Fulfill(jobID string, pri int64) {
+	publish(Event{
+		Tube:      w.Tube,
+		Kind:      EventAwaitingFulfilled,
+		JobID:     jobID,
+		Pri:       pri,
+		Timestamp: time.Now().Unix(),
+	})
+}
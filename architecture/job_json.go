@@ -0,0 +1,77 @@
+package architecture
+
+import "encoding/json"
+
+// jobJSON is the on-the-wire shape of a Job. Job's own fields are a mix
+// of exported and unexported (id and state in particular), so the default
+// json.Marshal/Unmarshal behavior silently drops the unexported ones;
+// jobJSON exports everything so a Job survives a round trip, which
+// store.Queue relies on to persist and recover jobs correctly.
+type jobJSON struct {
+	Id             string
+	Tube           string
+	Pri            int64
+	Delay          int64
+	StartedDelayAt int64
+	StartedTTRAt   int64
+	TTR            int64
+	Bytes          int64
+	Data           string
+	State          State
+	Spilled        bool
+	SpillRef       SpillRef
+}
+
+func (j *Job) toJSON(state State) jobJSON {
+	return jobJSON{
+		Id:             j.id,
+		Tube:           j.Tube,
+		Pri:            j.Pri,
+		Delay:          j.Delay,
+		StartedDelayAt: j.StartedDelayAt,
+		StartedTTRAt:   j.StartedTTRAt,
+		TTR:            j.TTR,
+		Bytes:          j.Bytes,
+		Data:           j.Data,
+		State:          state,
+		Spilled:        j.spilled,
+		SpillRef:       j.spillRef,
+	}
+}
+
+// MarshalJSON implements json.Marshaler so id and state (both unexported)
+// survive encoding.
+func (j *Job) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.toJSON(j.state))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the counterpart to
+// MarshalJSON.
+func (j *Job) UnmarshalJSON(data []byte) error {
+	var jj jobJSON
+	if err := json.Unmarshal(data, &jj); err != nil {
+		return err
+	}
+	j.id = jj.Id
+	j.Tube = jj.Tube
+	j.Pri = jj.Pri
+	j.Delay = jj.Delay
+	j.StartedDelayAt = jj.StartedDelayAt
+	j.StartedTTRAt = jj.StartedTTRAt
+	j.TTR = jj.TTR
+	j.Bytes = jj.Bytes
+	j.Data = jj.Data
+	j.state = jj.State
+	j.spilled = jj.Spilled
+	j.spillRef = jj.SpillRef
+	return nil
+}
+
+// EncodeWithState returns the JSON encoding j would have if it were
+// already in state, without mutating j or publishing an Event. It exists
+// for callers like store.Queue.Reserve that must write a tentative record
+// before a distributed compare-and-swap confirms the transition actually
+// won; only once it has should the caller mutate j for real via SetState.
+func EncodeWithState(j *Job, state State) ([]byte, error) {
+	return json.Marshal(j.toJSON(state))
+}
@@ -0,0 +1,69 @@
+package architecture
+
+import "errors"
+
+// ErrNoBodyStore is returned by Job.Body when the job's payload has been
+// spilled to disk but no BodyStore has been installed to fetch it back.
+var ErrNoBodyStore = errors.New("architecture: job body spilled but no BodyStore installed")
+
+// ErrForeignSpillRef is returned by a BodyStore when asked for a body
+// spilled on a different node than the one handling the request. A
+// store.Queue record is shared across every replica, but a SpillRef
+// names a file and offset on whichever node happened to spill that job;
+// a BodyStore that isn't itself replicated must refuse a foreign ref
+// rather than read whatever bytes happen to sit at that offset in its
+// own local log.
+var ErrForeignSpillRef = errors.New("architecture: job body was spilled on a different node")
+
+// SpillRef locates a job body that has been moved out of memory into an
+// on-disk spill log. Node identifies which node's log it was spilled
+// into, so a BodyStore can tell a same-node ref from a foreign one.
+type SpillRef struct {
+	Node   string
+	File   string
+	Offset int64
+	Length int64
+}
+
+// BodyStore fetches a spilled job body back from wherever Spill put it.
+// It is implemented by package spill.
+type BodyStore interface {
+	Body(ref SpillRef) ([]byte, error)
+}
+
+// BodySource is the installed BodyStore, if any. It must be set before
+// any Job.Spill is used; it is nil (and unused) for jobs that never spill.
+var BodySource BodyStore
+
+// Body returns the job's payload, fetching it from BodySource if it has
+// been spilled to disk. For a resident job this is just Data, with no I/O.
+func (j *Job) Body() ([]byte, error) {
+	if !j.spilled {
+		return []byte(j.Data), nil
+	}
+	if BodySource == nil {
+		return nil, ErrNoBodyStore
+	}
+	return BodySource.Body(j.spillRef)
+}
+
+// Spill records that the job's payload now lives at ref and drops the
+// in-memory copy, so idle buried/delayed jobs don't hold their body in
+// RAM. The priority key (Key()) is unaffected since it never depends on
+// Data.
+func (j *Job) Spill(ref SpillRef) {
+	j.Data = ""
+	j.spilled = true
+	j.spillRef = ref
+}
+
+// Spilled reports whether the job's body currently lives on disk.
+func (j *Job) Spilled() bool {
+	return j.spilled
+}
+
+// SpillRef returns where the job's body currently lives on disk, and
+// false if it is still resident.
+func (j *Job) SpillRef() (SpillRef, bool) {
+	return j.spillRef, j.spilled
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/streadway/amqp"
+)
+
+// AMQPSink publishes events to a 0.9.1 broker (e.g. RabbitMQ). The
+// exchange is declared once up front; the routing key is built per event
+// from the routingKeyTemplate given to NewAMQPSink, with "{tube}" and
+// "{kind}" substituted.
+type AMQPSink struct {
+	channel            *amqp.Channel
+	exchange           string
+	routingKeyTemplate string
+}
+
+// NewAMQPSink dials url, declares a topic exchange named exchange, and
+// returns a Sink that publishes to it. routingKeyTemplate may contain
+// "{tube}" and "{kind}" placeholders, e.g. "beanstalkg.{tube}.{kind}".
+func NewAMQPSink(url, exchange, routingKeyTemplate string) (*AMQPSink, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("notify: amqp dial: %v", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("notify: amqp channel: %v", err)
+	}
+	if err := ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		return nil, fmt.Errorf("notify: amqp exchange declare: %v", err)
+	}
+	return &AMQPSink{channel: ch, exchange: exchange, routingKeyTemplate: routingKeyTemplate}, nil
+}
+
+// Publish implements Sink.
+func (s *AMQPSink) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	routingKey := s.routingKey(e)
+	return s.channel.Publish(s.exchange, routingKey, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+	})
+}
+
+func (s *AMQPSink) routingKey(e Event) string {
+	r := strings.NewReplacer(
+		"{tube}", e.Tube,
+		"{kind}", fmt.Sprintf("%d", e.Kind),
+	)
+	return r.Replace(s.routingKeyTemplate)
+}
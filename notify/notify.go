@@ -0,0 +1,157 @@
+// Package notify fans job lifecycle events out to external systems
+// (message queues, caches, webhooks) configured per tube, analogous to S3
+// bucket notifications.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// Event is what a Sink actually receives: an architecture.Event enriched
+// with the job body, present only when the target that matched it asked
+// for it.
+type Event struct {
+	architecture.Event
+	Body []byte // nil unless the matching Target has IncludeBody set
+}
+
+// Sink delivers a single Event to an external system.
+type Sink interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Filter mirrors the S3 notification filter model: a target fires for an
+// event only if both Kinds and Tubes match (empty matches everything).
+type Filter struct {
+	Kinds []architecture.EventKind
+	Tubes []string // shell glob patterns, matched with filepath.Match
+}
+
+func (f Filter) match(e architecture.Event) bool {
+	if len(f.Kinds) > 0 {
+		found := false
+		for _, k := range f.Kinds {
+			if k == e.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Tubes) > 0 {
+		found := false
+		for _, pattern := range f.Tubes {
+			if ok, _ := filepath.Match(pattern, e.Tube); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Target binds a Sink to a Filter and a queue depth. IncludeBody controls
+// whether the job body is attached to events delivered to this target.
+type Target struct {
+	Name        string
+	Sink        Sink
+	Filter      Filter
+	IncludeBody bool
+	QueueDepth  int // defaults to 64 if <= 0
+}
+
+// Config is the on-disk shape of a notify configuration file.
+type Config struct {
+	Targets []Target
+}
+
+// LoadConfig reads and parses a notify configuration file. Sinks must be
+// constructed and assigned to the returned Targets by the caller, since
+// Sink construction needs credentials that don't belong in this file.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg := new(Config)
+	if err := json.NewDecoder(f).Decode(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// BodyFunc resolves a job's body for events that need it attached.
+// It is looked up lazily, only for targets that asked for it.
+type BodyFunc func(jobID string) []byte
+
+// Dispatcher delivers architecture.Events to a set of Targets, each
+// through its own bounded queue and goroutine so a slow target cannot
+// stall SetState. It implements architecture.Sink, so it can be installed
+// directly as architecture.Publisher.
+type Dispatcher struct {
+	targets []dispatchTarget
+	bodyFn  BodyFunc
+}
+
+type dispatchTarget struct {
+	Target
+	queue chan Event
+}
+
+// NewDispatcher starts one delivery goroutine per target. bodyFn may be
+// nil if no target has IncludeBody set.
+func NewDispatcher(targets []Target, bodyFn BodyFunc) *Dispatcher {
+	d := &Dispatcher{bodyFn: bodyFn}
+	for _, t := range targets {
+		depth := t.QueueDepth
+		if depth <= 0 {
+			depth = 64
+		}
+		dt := dispatchTarget{Target: t, queue: make(chan Event, depth)}
+		d.targets = append(d.targets, dt)
+		go d.run(dt)
+	}
+	return d
+}
+
+// Publish implements architecture.Sink. It never blocks: a target whose
+// queue is full drops the event rather than stalling the caller.
+func (d *Dispatcher) Publish(e architecture.Event) {
+	for _, dt := range d.targets {
+		if !dt.Filter.match(e) {
+			continue
+		}
+		ne := Event{Event: e}
+		if dt.IncludeBody && d.bodyFn != nil {
+			ne.Body = d.bodyFn(e.JobID)
+		}
+		select {
+		case dt.queue <- ne:
+		default:
+			log.Printf("notify: target %q queue full, dropping event for job %s", dt.Name, e.JobID)
+		}
+	}
+}
+
+func (d *Dispatcher) run(dt dispatchTarget) {
+	for e := range dt.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := dt.Sink.Publish(ctx, e); err != nil {
+			log.Printf("notify: target %q delivery failed for job %s: %v", dt.Name, e.JobID, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// recordingSink collects every Event it's asked to Publish.
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+	done   chan struct{}
+}
+
+func newRecordingSink(want int) *recordingSink {
+	return &recordingSink{done: make(chan struct{}, want)}
+}
+
+func (s *recordingSink) Publish(_ context.Context, e Event) error {
+	s.mu.Lock()
+	s.events = append(s.events, e)
+	s.mu.Unlock()
+	s.done <- struct{}{}
+	return nil
+}
+
+func (s *recordingSink) waitFor(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.done:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d/%d", i+1, n)
+		}
+	}
+}
+
+func TestDispatcherDeliversOnlyMatchingEvents(t *testing.T) {
+	sink := newRecordingSink(1)
+	d := NewDispatcher([]Target{{
+		Name:   "only-deletes",
+		Sink:   sink,
+		Filter: Filter{Kinds: []architecture.EventKind{architecture.EventDeleted}},
+	}}, nil)
+
+	d.Publish(architecture.Event{Tube: "t", Kind: architecture.EventStateChange, JobID: "wrong-kind"})
+	d.Publish(architecture.Event{Tube: "t", Kind: architecture.EventDeleted, JobID: "match"})
+	sink.waitFor(t, 1)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.events) != 1 || sink.events[0].JobID != "match" {
+		t.Fatalf("got events %+v, want exactly one event for job %q", sink.events, "match")
+	}
+}
+
+func TestDispatcherAttachesBodyWhenIncludeBodySet(t *testing.T) {
+	sink := newRecordingSink(1)
+	bodyFn := func(jobID string) []byte { return []byte("body-of-" + jobID) }
+	d := NewDispatcher([]Target{{
+		Name:        "with-body",
+		Sink:        sink,
+		IncludeBody: true,
+	}}, bodyFn)
+
+	d.Publish(architecture.Event{Tube: "t", JobID: "1"})
+	sink.waitFor(t, 1)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if string(sink.events[0].Body) != "body-of-1" {
+		t.Fatalf("Body = %q, want %q", sink.events[0].Body, "body-of-1")
+	}
+}
+
+func TestDispatcherDropsEventWhenTargetQueueIsFull(t *testing.T) {
+	blocked := make(chan struct{})
+	sink := &blockingSink{unblock: blocked}
+	d := NewDispatcher([]Target{{
+		Name:       "slow",
+		Sink:       sink,
+		QueueDepth: 1,
+	}}, nil)
+	defer close(blocked)
+
+	// The first event is picked up by run() and blocks there, the second
+	// fills the depth-1 queue, and the third must be dropped rather than
+	// stalling Publish.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			d.Publish(architecture.Event{Tube: "t", JobID: "x"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked instead of dropping the event for a full queue")
+	}
+}
+
+// blockingSink blocks its first Publish until unblock is closed, to
+// exercise the Dispatcher's drop-on-full behavior without a real sink.
+type blockingSink struct {
+	once    sync.Once
+	unblock chan struct{}
+}
+
+func (s *blockingSink) Publish(_ context.Context, _ Event) error {
+	s.once.Do(func() { <-s.unblock })
+	return nil
+}
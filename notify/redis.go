@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// RedisMode selects how RedisSink hands events to Redis.
+type RedisMode int
+
+const (
+	// RedisModeList LPUSHes the JSON-encoded event onto a list key.
+	RedisModeList RedisMode = iota
+	// RedisModePubSub PUBLISHes the JSON-encoded event to a channel.
+	RedisModePubSub
+)
+
+// RedisSink writes events to Redis, either as a list (for consumers that
+// poll with BRPOP) or as a pub/sub channel.
+type RedisSink struct {
+	pool *redis.Pool
+	mode RedisMode
+	key  string // list key or channel name, depending on mode
+}
+
+// NewRedisSink returns a Sink backed by a Redis connection pool dialing
+// addr on first use.
+func NewRedisSink(addr string, mode RedisMode, key string) *RedisSink {
+	pool := &redis.Pool{
+		MaxIdle:     8,
+		IdleTimeout: 0,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr)
+		},
+	}
+	return &RedisSink{pool: pool, mode: mode, key: key}
+}
+
+// Publish implements Sink.
+func (s *RedisSink) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	switch s.mode {
+	case RedisModeList:
+		_, err = conn.Do("LPUSH", s.key, body)
+	case RedisModePubSub:
+		_, err = conn.Do("PUBLISH", s.key, body)
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookSink POSTs the JSON-encoded event to a URL, retrying on failure
+// with exponential backoff.
+type WebhookSink struct {
+	client     *http.Client
+	url        string
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// NewWebhookSink returns a Sink that POSTs to url, retrying up to
+// maxRetries times with exponential backoff starting at baseDelay.
+func NewWebhookSink(url string, maxRetries int, baseDelay time.Duration) *WebhookSink {
+	return &WebhookSink{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		url:        url,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+	}
+}
+
+// Publish implements Sink.
+func (s *WebhookSink) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	delay := s.baseDelay
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+		req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("notify: webhook %s returned %s", s.url, resp.Status)
+	}
+	return lastErr
+}
@@ -0,0 +1,100 @@
+package spill
+
+import (
+	"os"
+	"time"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// LiveRatio returns the fraction of the spill log's on-disk bytes that are
+// still referenced by a live job. A low ratio means most of the file is
+// dead weight left behind by jobs that were deleted or brought back
+// resident.
+func (m *Manager) LiveRatio() float64 {
+	m.mu.Lock()
+	var liveBytes int64
+	for ref := range m.live {
+		liveBytes += ref.Length
+	}
+	size := m.log.Size()
+	m.mu.Unlock()
+	if size == 0 {
+		return 1
+	}
+	return float64(liveBytes) / float64(size)
+}
+
+// Compact rewrites the log keeping only bodies a job still references,
+// repointing each job's SpillRef at its new location.
+//
+// m.mu is held for the entire operation, not just the m.live snapshot: a
+// MaybeSpill that slipped in between the snapshot and the old log's
+// removal would append to a file about to be discarded and never be
+// reflected in newLive, silently losing that job's body. Compaction is a
+// background maintenance op, not something on the hot path, so
+// serializing it against spills is the cheap way to stay correct.
+func (m *Manager) Compact() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.live) == 0 {
+		return nil
+	}
+	oldPath := m.log.file.Name()
+
+	newLog, err := OpenLog(oldPath + ".compact")
+	if err != nil {
+		return err
+	}
+
+	newLive := make(map[architecture.SpillRef]*architecture.Job, len(m.live))
+	for ref, j := range m.live {
+		body, err := m.log.Body(ref)
+		if err != nil {
+			newLog.Close()
+			return err
+		}
+		newRef, err := newLog.Append(body)
+		if err != nil {
+			newLog.Close()
+			return err
+		}
+		newRef.Node = m.node
+		newRef.File = oldPath // the .compact file is renamed to oldPath below
+		j.Spill(newRef)
+		newLive[newRef] = j
+	}
+
+	if err := m.log.Close(); err != nil {
+		newLog.Close()
+		return err
+	}
+	if err := newLog.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(newLog.file.Name(), oldPath); err != nil {
+		return err
+	}
+
+	reopened, err := OpenLog(oldPath)
+	if err != nil {
+		return err
+	}
+
+	m.log = reopened
+	m.live = newLive
+	return nil
+}
+
+// RunCompactor periodically compacts the log whenever LiveRatio drops
+// below threshold. It blocks, so call it in its own goroutine.
+func (m *Manager) RunCompactor(interval time.Duration, threshold float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if m.LiveRatio() < threshold {
+			m.Compact()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package spill implements an on-disk overflow log for job bodies, so a
+// tube with an operator-tunable memory budget can keep its priority
+// index resident while evicting large, idle payloads to disk.
+package spill
+
+import (
+	"os"
+	"sync"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// Log is an append-only file that job bodies are spilled into. Nothing is
+// ever overwritten in place; Manager.Compact is what reclaims space from
+// entries no job references any more.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenLog opens (creating if necessary) the spill log at path.
+func OpenLog(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Log{file: f, size: info.Size()}, nil
+}
+
+// Append writes body to the end of the log and returns a ref locating it.
+func (l *Log) Append(body []byte) (architecture.SpillRef, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	off := l.size
+	n, err := l.file.WriteAt(body, off)
+	if err != nil {
+		return architecture.SpillRef{}, err
+	}
+	l.size += int64(n)
+	return architecture.SpillRef{File: l.file.Name(), Offset: off, Length: int64(n)}, nil
+}
+
+// Body implements architecture.BodyStore.
+func (l *Log) Body(ref architecture.SpillRef) ([]byte, error) {
+	buf := make([]byte, ref.Length)
+	if _, err := l.file.ReadAt(buf, ref.Offset); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Size returns the current on-disk size of the log.
+func (l *Log) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// Close closes the underlying file.
+func (l *Log) Close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,121 @@
+package spill
+
+import (
+	"sync"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// Config holds the operator-tunable limits behind --max-memtable-bytes
+// and --value-threshold.
+type Config struct {
+	MaxMemtableBytes int64 // per-tube budget for resident job bodies
+	ValueThreshold   int64 // bodies smaller than this always stay resident
+}
+
+// Manager enforces a per-tube memory budget for job bodies: once a
+// tube's resident set exceeds MaxMemtableBytes, bodies of at least
+// ValueThreshold bytes are moved to an on-disk Log and fetched back on demand
+// through architecture.Job.Body. It also tracks resident/spilled byte
+// metrics per tube.
+type Manager struct {
+	cfg  Config
+	log  *Log
+	node string // stamped on every SpillRef this Manager creates
+
+	mu       sync.Mutex
+	resident map[string]int64                            // tube -> resident bytes
+	spilled  map[string]int64                            // tube -> spilled bytes
+	live     map[architecture.SpillRef]*architecture.Job // ref -> owning job, for Compact
+}
+
+// NewManager returns a Manager that spills into log according to cfg,
+// stamping node onto every SpillRef it creates, and installs itself as
+// architecture.BodySource. node must be unique per cluster member: a
+// Queue record is shared across replicas, but the spill log underneath
+// log is not, so Manager.Body uses node to refuse a ref that names a
+// different node instead of silently reading the wrong bytes.
+func NewManager(cfg Config, log *Log, node string) *Manager {
+	m := &Manager{
+		cfg:      cfg,
+		log:      log,
+		node:     node,
+		resident: make(map[string]int64),
+		spilled:  make(map[string]int64),
+		live:     make(map[architecture.SpillRef]*architecture.Job),
+	}
+	architecture.BodySource = m
+	return m
+}
+
+// Body implements architecture.BodyStore. It refuses a ref spilled by a
+// different node rather than read whatever bytes happen to sit at that
+// offset in this node's own log.
+func (m *Manager) Body(ref architecture.SpillRef) ([]byte, error) {
+	if ref.Node != m.node {
+		return nil, architecture.ErrForeignSpillRef
+	}
+	return m.log.Body(ref)
+}
+
+// Track records a newly-resident job body. Call it once when a job's
+// Data becomes resident, e.g. on put, release, or kick.
+func (m *Manager) Track(tube string, bodyBytes int64) {
+	m.mu.Lock()
+	m.resident[tube] += bodyBytes
+	m.mu.Unlock()
+}
+
+// MaybeSpill moves j's body to disk if tube is over its memory budget and
+// the body is large enough to be worth spilling. It is a no-op otherwise.
+//
+// The whole check-append-record sequence runs under m.mu, both so the
+// resident-bytes check and the append against m.log are consistent with
+// each other, and so a concurrent Compact can't swap m.log (or miss this
+// ref in the live set it rebuilds) mid-spill.
+func (m *Manager) MaybeSpill(tube string, j *architecture.Job) error {
+	bodyLen := int64(len(j.Data))
+	if bodyLen == 0 || bodyLen < m.cfg.ValueThreshold {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	over := m.cfg.MaxMemtableBytes > 0 && m.resident[tube] > m.cfg.MaxMemtableBytes
+	if !over {
+		return nil
+	}
+
+	ref, err := m.log.Append([]byte(j.Data))
+	if err != nil {
+		return err
+	}
+	ref.Node = m.node
+	j.Spill(ref)
+
+	m.resident[tube] -= bodyLen
+	m.spilled[tube] += bodyLen
+	m.live[ref] = j
+	return nil
+}
+
+// Forget releases accounting for a job that has been deleted, or whose
+// body was brought back resident and is no longer tied to ref.
+func (m *Manager) Forget(tube string, j *architecture.Job) {
+	ref, wasSpilled := j.SpillRef()
+	if !wasSpilled {
+		return
+	}
+	m.mu.Lock()
+	m.spilled[tube] -= ref.Length
+	delete(m.live, ref)
+	m.mu.Unlock()
+}
+
+// Metrics returns the current resident and spilled byte counts for tube.
+func (m *Manager) Metrics(tube string) (resident, spilled int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.resident[tube], m.spilled[tube]
+}
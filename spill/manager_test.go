@@ -0,0 +1,73 @@
+package spill
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+func TestCompactPreservesSpilledBodies(t *testing.T) {
+	log, err := OpenLog(filepath.Join(t.TempDir(), "spill.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(Config{MaxMemtableBytes: 1, ValueThreshold: 1}, log, "node-a")
+
+	kept := architecture.NewJob("t", "kept", 0, 0, 60, 5, "hello")
+	dropped := architecture.NewJob("t", "dropped", 0, 0, 60, 5, "world")
+	for _, j := range []*architecture.Job{kept, dropped} {
+		m.Track("t", int64(len(j.Data)))
+		if err := m.MaybeSpill("t", j); err != nil {
+			t.Fatal(err)
+		}
+		if !j.Spilled() {
+			t.Fatalf("job %s did not spill", j.Id())
+		}
+	}
+
+	// Only "kept" is still referenced; Compact should drop "dropped"'s
+	// bytes from the rewritten log.
+	m.Forget("t", dropped)
+
+	if err := m.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := kept.Body()
+	if err != nil {
+		t.Fatalf("Body() after Compact: %v", err)
+	}
+	if string(body) != "hello" {
+		t.Fatalf("Body() = %q, want %q", body, "hello")
+	}
+
+	ref, _ := kept.SpillRef()
+	if ref.Node != "node-a" {
+		t.Fatalf("SpillRef().Node = %q, want %q", ref.Node, "node-a")
+	}
+
+	if got := m.LiveRatio(); got != 1 {
+		t.Fatalf("LiveRatio() after Compact = %v, want 1 (only live bytes remain)", got)
+	}
+}
+
+func TestBodyRejectsRefFromAnotherNode(t *testing.T) {
+	log, err := OpenLog(filepath.Join(t.TempDir(), "spill.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(Config{MaxMemtableBytes: 1, ValueThreshold: 1}, log, "node-a")
+
+	j := architecture.NewJob("t", "1", 0, 0, 60, 5, "hello")
+	m.Track("t", int64(len(j.Data)))
+	if err := m.MaybeSpill("t", j); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, _ := j.SpillRef()
+	ref.Node = "node-b"
+	if _, err := m.Body(ref); err != architecture.ErrForeignSpillRef {
+		t.Fatalf("Body() err = %v, want ErrForeignSpillRef", err)
+	}
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"time"
+
+	"github.com/docker/libkv/store"
+)
+
+// Elector campaigns for exclusive leadership of the cluster using the
+// backend's distributed lock primitive. Only the leader should run
+// delayed-timer processing and TTR expiry sweeps. Nothing in this package
+// forwards commands from followers to the leader.
+type Elector struct {
+	lock store.Locker
+}
+
+// NewElector returns an Elector that campaigns on key, holding the lock
+// for ttl at a time (libkv renews it automatically while held).
+func NewElector(kv store.Store, key string, ttl time.Duration) (*Elector, error) {
+	lock, err := kv.NewLock(key, &store.LockOptions{TTL: ttl})
+	if err != nil {
+		return nil, err
+	}
+	return &Elector{lock: lock}, nil
+}
+
+// Campaign blocks until this node becomes leader or stopCh is closed. On
+// success it returns a channel that is closed once leadership is lost,
+// whether by Resign or by the backend connection dropping out from under
+// the lock; callers should re-Campaign in that case rather than treat it
+// as fatal, so a backend outage never permanently strands the cluster
+// leaderless.
+func (e *Elector) Campaign(stopCh chan struct{}) (lost <-chan struct{}, err error) {
+	return e.lock.Lock(stopCh)
+}
+
+// Resign releases leadership early.
+func (e *Elector) Resign() error {
+	return e.lock.Unlock()
+}
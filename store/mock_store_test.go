@@ -0,0 +1,130 @@
+package store
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/docker/libkv/store"
+)
+
+// mockStore is a minimal in-memory store.Store, just enough to exercise
+// Queue's CAS-based transitions in tests. It is not a general-purpose
+// libkv implementation: Watch/WatchTree/NewLock are left unsupported
+// since no test here needs them.
+type mockStore struct {
+	mu      sync.Mutex
+	items   map[string]*store.KVPair
+	lastIdx uint64
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{items: make(map[string]*store.KVPair)}
+}
+
+func (m *mockStore) Put(key string, value []byte, _ *store.WriteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastIdx++
+	m.items[key] = &store.KVPair{Key: key, Value: value, LastIndex: m.lastIdx}
+	return nil
+}
+
+func (m *mockStore) Get(key string) (*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.items[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (m *mockStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+func (m *mockStore) Exists(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.items[key]
+	return ok, nil
+}
+
+func (m *mockStore) Watch(key string, stopCh <-chan struct{}) (<-chan *store.KVPair, error) {
+	return nil, store.ErrCallNotSupported
+}
+
+func (m *mockStore) WatchTree(directory string, stopCh <-chan struct{}) (<-chan []*store.KVPair, error) {
+	return nil, store.ErrCallNotSupported
+}
+
+func (m *mockStore) NewLock(key string, options *store.LockOptions) (store.Locker, error) {
+	return nil, store.ErrCallNotSupported
+}
+
+func (m *mockStore) List(directory string) ([]*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := directory + "/"
+	var out []*store.KVPair
+	for k, p := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			cp := *p
+			out = append(out, &cp)
+		}
+	}
+	if len(out) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+	return out, nil
+}
+
+func (m *mockStore) DeleteTree(directory string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := directory + "/"
+	for k := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.items, k)
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) AtomicPut(key string, value []byte, previous *store.KVPair, _ *store.WriteOptions) (bool, *store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, exists := m.items[key]
+	if previous == nil {
+		if exists {
+			return false, nil, store.ErrKeyExists
+		}
+	} else if !exists || cur.LastIndex != previous.LastIndex {
+		return false, nil, store.ErrKeyModified
+	}
+	m.lastIdx++
+	np := &store.KVPair{Key: key, Value: value, LastIndex: m.lastIdx}
+	m.items[key] = np
+	cp := *np
+	return true, &cp, nil
+}
+
+func (m *mockStore) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, exists := m.items[key]
+	if !exists {
+		return false, store.ErrKeyNotFound
+	}
+	if previous == nil || cur.LastIndex != previous.LastIndex {
+		return false, store.ErrKeyModified
+	}
+	delete(m.items, key)
+	return true, nil
+}
+
+func (m *mockStore) Close() {}
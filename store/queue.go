@@ -0,0 +1,283 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/docker/libkv/store"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// record is the JSON-serialized form of a job as kept in the KV store.
+type record struct {
+	Job *architecture.Job
+	Key int64
+}
+
+// Queue is a distributed view of one tube's ready, delayed, reserved and
+// buried sets, backed by a libkv store.Store. Every mutating call writes
+// through the backend, so all replicas see the same records regardless
+// of which node a client talked to. Reserve claims a job with a
+// compare-and-swap; Release, Bury and Kick write the new record with a
+// plain put and then clear the old one with a compare-and-delete; Put,
+// Delete and Requeue use plain writes.
+type Queue struct {
+	kv   store.Store
+	tube string
+}
+
+// NewQueue returns a Queue for tube backed by kv.
+func NewQueue(kv store.Store, tube string) *Queue {
+	return &Queue{kv: kv, tube: tube}
+}
+
+func (q *Queue) dir(state architecture.State) string {
+	return fmt.Sprintf("beanstalkg/tubes/%s/%d", q.tube, state)
+}
+
+func (q *Queue) key(state architecture.State, jobID string) string {
+	return q.dir(state) + "/" + jobID
+}
+
+func encode(j *architecture.Job) ([]byte, error) {
+	return json.Marshal(record{Job: j, Key: j.Key()})
+}
+
+// Put durably writes j under its current state.
+func (q *Queue) Put(j *architecture.Job) error {
+	body, err := encode(j)
+	if err != nil {
+		return err
+	}
+	return q.kv.Put(q.key(j.State(), j.Id()), body, nil)
+}
+
+// Reserve atomically moves j from ready to reserved, writing a lease that
+// expires after ttr. If the backend's TTL fires before Release, Bury or a
+// further Reserve touches the key, the entry disappears and the leader's
+// background sweep (see Queue.Requeue) puts the job back in ready.
+// Reserve reports false, nil if another replica reserved j first; in that
+// case j is left untouched, since the reservation belongs to whichever
+// replica's CAS actually won.
+func (q *Queue) Reserve(j *architecture.Job, ttr time.Duration) (bool, error) {
+	readyKey := q.key(architecture.READY, j.Id())
+	prev, err := q.kv.Get(readyKey)
+	if err == store.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Build the reserved record without mutating j: until the CAS below
+	// confirms this replica actually won the reservation, j must stay
+	// exactly as the caller handed it to us.
+	placeholder, err := architecture.EncodeWithState(j, architecture.RESERVED)
+	if err != nil {
+		return false, err
+	}
+	ok, _, err := q.kv.AtomicPut(q.key(architecture.RESERVED, j.Id()), placeholder, nil, &store.WriteOptions{TTL: ttr})
+	if err == store.ErrKeyExists {
+		return false, nil
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// Only now, with the reservation durable and exclusive, mutate j for
+	// real so StartedTTRAt is set and the lifecycle Event fires.
+	if err := j.SetState(architecture.RESERVED); err != nil {
+		return false, err
+	}
+	if body, err := encode(j); err == nil {
+		// Best effort: refresh the record with the real StartedTTRAt now
+		// that it's known, so Key() reflects the actual TTR countdown
+		// rather than the placeholder's. Losing this write isn't fatal;
+		// the placeholder is still a valid (if slightly stale) record.
+		// The rewrite carries the same ttr lease as the placeholder.
+		q.kv.Put(q.key(architecture.RESERVED, j.Id()), body, &store.WriteOptions{TTL: ttr})
+	}
+
+	// Clear the ready entry last. The reservation already succeeded and
+	// durably recorded above, so a failure here must not be reported back
+	// as a failed Reserve: that would leave the caller's retained Job
+	// reserved while the caller believes it never won it. Leaving the
+	// stale ready key behind is harmless (the reserved key's CAS above
+	// already made the reservation exclusive, so nothing can reserve j
+	// twice), so clean it up best-effort in the background instead.
+	if ok, err := q.kv.AtomicDelete(readyKey, prev); err != nil || !ok {
+		log.Printf("store: reserved job %s but could not clear its stale ready key (ok=%v err=%v); retrying in background", j.Id(), ok, err)
+		go q.retryClearKey(readyKey, prev)
+	}
+	return true, nil
+}
+
+// retryClearKey retries an AtomicDelete whose previous attempt failed,
+// for a key whose owning state transition already succeeded durably;
+// the delete is cleanup of a now-stale record, not part of the
+// transition itself, so failures here are logged rather than surfaced.
+// It stops as soon as the key is gone or has been rewritten since prev
+// was read, since a rewritten key is no longer the stale record.
+func (q *Queue) retryClearKey(key string, prev *store.KVPair) {
+	delay := time.Second
+	for attempt := 0; attempt < 5; attempt++ {
+		time.Sleep(delay)
+		if ok, err := q.kv.AtomicDelete(key, prev); err == nil && ok {
+			return
+		}
+		p, err := q.kv.Get(key)
+		if err == store.ErrKeyNotFound {
+			return
+		}
+		if err == nil && p.LastIndex != prev.LastIndex {
+			return
+		}
+		delay *= 2
+	}
+	log.Printf("store: giving up clearing stale key %s after retries", key)
+}
+
+// Release moves a reserved job back to ready (or delayed, if delay > 0),
+// clearing its reservation lease.
+func (q *Queue) Release(j *architecture.Job, delay int64) error {
+	reservedKey := q.key(architecture.RESERVED, j.Id())
+	prev, err := q.kv.Get(reservedKey)
+	if err != nil {
+		return err
+	}
+
+	next := architecture.READY
+	if delay > 0 {
+		next = architecture.DELAYED
+	}
+
+	// Durably record the transition before mutating j, exactly as Reserve
+	// does for its CAS: if this Put fails, j must be left exactly as the
+	// caller handed it to us, not half-released.
+	placeholder, err := architecture.EncodeWithState(j, next)
+	if err != nil {
+		return err
+	}
+	if err := q.kv.Put(q.key(next, j.Id()), placeholder, nil); err != nil {
+		return err
+	}
+
+	j.Delay = delay
+	if err := j.SetState(next); err != nil {
+		return err
+	}
+	if body, err := encode(j); err == nil {
+		// Best effort: refresh with the real Delay/StartedDelayAt now
+		// that they're known, same as Reserve's StartedTTRAt refresh.
+		q.kv.Put(q.key(next, j.Id()), body, nil)
+	}
+
+	if ok, err := q.kv.AtomicDelete(reservedKey, prev); err != nil || !ok {
+		log.Printf("store: released job %s but could not clear its stale reserved key (ok=%v err=%v); retrying in background", j.Id(), ok, err)
+		go q.retryClearKey(reservedKey, prev)
+	}
+	return nil
+}
+
+// Bury moves a reserved job to buried, where it stays until Kick.
+func (q *Queue) Bury(j *architecture.Job) error {
+	reservedKey := q.key(architecture.RESERVED, j.Id())
+	prev, err := q.kv.Get(reservedKey)
+	if err != nil {
+		return err
+	}
+
+	// Durably record the transition before mutating j; see Release.
+	placeholder, err := architecture.EncodeWithState(j, architecture.BURIED)
+	if err != nil {
+		return err
+	}
+	if err := q.kv.Put(q.key(architecture.BURIED, j.Id()), placeholder, nil); err != nil {
+		return err
+	}
+
+	if err := j.SetState(architecture.BURIED); err != nil {
+		return err
+	}
+
+	if ok, err := q.kv.AtomicDelete(reservedKey, prev); err != nil || !ok {
+		log.Printf("store: buried job %s but could not clear its stale reserved key (ok=%v err=%v); retrying in background", j.Id(), ok, err)
+		go q.retryClearKey(reservedKey, prev)
+	}
+	return nil
+}
+
+// Kick moves a buried job back to ready.
+func (q *Queue) Kick(j *architecture.Job) error {
+	buriedKey := q.key(architecture.BURIED, j.Id())
+	prev, err := q.kv.Get(buriedKey)
+	if err != nil {
+		return err
+	}
+
+	// Durably record the transition before mutating j; see Release.
+	placeholder, err := architecture.EncodeWithState(j, architecture.READY)
+	if err != nil {
+		return err
+	}
+	if err := q.kv.Put(q.key(architecture.READY, j.Id()), placeholder, nil); err != nil {
+		return err
+	}
+
+	if err := j.SetState(architecture.READY); err != nil {
+		return err
+	}
+
+	if ok, err := q.kv.AtomicDelete(buriedKey, prev); err != nil || !ok {
+		log.Printf("store: kicked job %s but could not clear its stale buried key (ok=%v err=%v); retrying in background", j.Id(), ok, err)
+		go q.retryClearKey(buriedKey, prev)
+	}
+	return nil
+}
+
+// Delete removes j from whichever state it currently occupies.
+func (q *Queue) Delete(j *architecture.Job) error {
+	j.Delete()
+	return q.kv.Delete(q.key(j.State(), j.Id()))
+}
+
+// List returns every job currently recorded under state for this tube.
+// A reserved job whose lease has expired is no longer present in the
+// reserved set.
+func (q *Queue) List(state architecture.State) ([]*architecture.Job, error) {
+	pairs, err := q.kv.List(q.dir(state))
+	if err == store.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*architecture.Job, 0, len(pairs))
+	for _, p := range pairs {
+		var r record
+		if err := json.Unmarshal(p.Value, &r); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, r.Job)
+	}
+	return jobs, nil
+}
+
+// Requeue is called by the leader for a reserved job whose lease has
+// expired (found missing from the reserved list's watch) to put it back
+// in ready with no delay, matching beanstalkd's TTR-expiry behaviour. It
+// uses SetStateTTRExpired rather than SetState so the resulting Event is
+// tagged EventTTRExpired instead of the ordinary EventReleased.
+func (q *Queue) Requeue(j *architecture.Job) error {
+	if err := j.SetStateTTRExpired(); err != nil {
+		return err
+	}
+	body, err := encode(j)
+	if err != nil {
+		return err
+	}
+	return q.kv.Put(q.key(architecture.READY, j.Id()), body, nil)
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/libkv/store"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+func TestReserveMovesReadyJobToReserved(t *testing.T) {
+	q := NewQueue(newMockStore(), "t")
+	j := architecture.NewJob("t", "1", 0, 0, 60, 3, "hi")
+	if err := q.Put(j); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := q.Reserve(j, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Reserve() = %v, %v, want true, nil", ok, err)
+	}
+	if j.State() != architecture.RESERVED {
+		t.Fatalf("job state = %v, want RESERVED", j.State())
+	}
+	if _, err := q.kv.Get(q.key(architecture.READY, j.Id())); err != store.ErrKeyNotFound {
+		t.Fatalf("ready key still present after Reserve: err = %v", err)
+	}
+	if _, err := q.kv.Get(q.key(architecture.RESERVED, j.Id())); err != nil {
+		t.Fatalf("reserved key missing after Reserve: %v", err)
+	}
+}
+
+func TestReserveReportsFalseWhenJobNotReady(t *testing.T) {
+	q := NewQueue(newMockStore(), "t")
+	j := architecture.NewJob("t", "1", 0, 0, 60, 3, "hi")
+
+	ok, err := q.Reserve(j, time.Minute)
+	if err != nil || ok {
+		t.Fatalf("Reserve() = %v, %v, want false, nil", ok, err)
+	}
+	if j.State() != architecture.READY {
+		t.Fatalf("job state = %v, want untouched READY", j.State())
+	}
+}
+
+func TestReleaseWithoutDelayReturnsJobToReady(t *testing.T) {
+	q := NewQueue(newMockStore(), "t")
+	j := architecture.NewJob("t", "1", 0, 0, 60, 3, "hi")
+	mustPutAndReserve(t, q, j)
+
+	if err := q.Release(j, 0); err != nil {
+		t.Fatal(err)
+	}
+	if j.State() != architecture.READY {
+		t.Fatalf("job state = %v, want READY", j.State())
+	}
+	if _, err := q.kv.Get(q.key(architecture.READY, j.Id())); err != nil {
+		t.Fatalf("ready key missing after Release: %v", err)
+	}
+	if _, err := q.kv.Get(q.key(architecture.RESERVED, j.Id())); err != store.ErrKeyNotFound {
+		t.Fatalf("reserved key still present after Release: err = %v", err)
+	}
+}
+
+func TestReleaseWithDelaySendsJobToDelayed(t *testing.T) {
+	q := NewQueue(newMockStore(), "t")
+	j := architecture.NewJob("t", "1", 0, 0, 60, 3, "hi")
+	mustPutAndReserve(t, q, j)
+
+	if err := q.Release(j, 10); err != nil {
+		t.Fatal(err)
+	}
+	if j.State() != architecture.DELAYED {
+		t.Fatalf("job state = %v, want DELAYED", j.State())
+	}
+	if j.Delay != 10 {
+		t.Fatalf("job Delay = %d, want 10", j.Delay)
+	}
+	if _, err := q.kv.Get(q.key(architecture.DELAYED, j.Id())); err != nil {
+		t.Fatalf("delayed key missing after Release: %v", err)
+	}
+}
+
+func TestBuryThenKickRoundTrip(t *testing.T) {
+	q := NewQueue(newMockStore(), "t")
+	j := architecture.NewJob("t", "1", 0, 0, 60, 3, "hi")
+	mustPutAndReserve(t, q, j)
+
+	if err := q.Bury(j); err != nil {
+		t.Fatal(err)
+	}
+	if j.State() != architecture.BURIED {
+		t.Fatalf("job state = %v, want BURIED", j.State())
+	}
+	if _, err := q.kv.Get(q.key(architecture.RESERVED, j.Id())); err != store.ErrKeyNotFound {
+		t.Fatalf("reserved key still present after Bury: err = %v", err)
+	}
+
+	if err := q.Kick(j); err != nil {
+		t.Fatal(err)
+	}
+	if j.State() != architecture.READY {
+		t.Fatalf("job state = %v, want READY", j.State())
+	}
+	if _, err := q.kv.Get(q.key(architecture.BURIED, j.Id())); err != store.ErrKeyNotFound {
+		t.Fatalf("buried key still present after Kick: err = %v", err)
+	}
+	if _, err := q.kv.Get(q.key(architecture.READY, j.Id())); err != nil {
+		t.Fatalf("ready key missing after Kick: %v", err)
+	}
+}
+
+func mustPutAndReserve(t *testing.T, q *Queue, j *architecture.Job) {
+	t.Helper()
+	if err := q.Put(j); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := q.Reserve(j, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Reserve() = %v, %v, want true, nil", ok, err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package store replicates tube state across a cluster through a
+// pluggable KV backend (etcd, Consul, or Zookeeper) via docker/libkv, so
+// that job queues and leadership are coordinated rather than held
+// in-process on a single node.
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/libkv"
+	"github.com/docker/libkv/store"
+	"github.com/docker/libkv/store/consul"
+	"github.com/docker/libkv/store/etcd"
+	"github.com/docker/libkv/store/zookeeper"
+)
+
+func init() {
+	etcd.Register()
+	consul.Register()
+	zookeeper.Register()
+}
+
+// Backend names accepted by --store-backend. BackendEtcd talks to etcd
+// over docker/libkv's v2 (HTTP/JSON) client; docker/libkv never shipped
+// a v3 (gRPC) driver, so that's the only etcd wire protocol this backend
+// can reach.
+const (
+	BackendEtcd      = "etcd"
+	BackendConsul    = "consul"
+	BackendZookeeper = "zookeeper"
+)
+
+// Config configures the distributed KV store backend, populated from the
+// --store-backend and --store-endpoints flags.
+type Config struct {
+	Backend   string
+	Endpoints []string
+	Timeout   time.Duration
+}
+
+// New dials the configured backend and returns a raw libkv store.Store
+// for Queue and Elector to build on.
+func New(cfg Config) (store.Store, error) {
+	var backend store.Backend
+	switch cfg.Backend {
+	case BackendEtcd:
+		backend = store.ETCD
+	case BackendConsul:
+		backend = store.CONSUL
+	case BackendZookeeper:
+		backend = store.ZK
+	default:
+		return nil, fmt.Errorf("store: unknown backend %q", cfg.Backend)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return libkv.NewStore(backend, cfg.Endpoints, &store.Config{
+		ConnectionTimeout: cfg.Timeout,
+	})
+}
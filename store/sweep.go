@@ -0,0 +1,110 @@
+package store
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// campaignRetryDelay bounds how often RunAsLeader retries a failed
+// Campaign, so a backend outage doesn't turn into a busy loop.
+const campaignRetryDelay = 5 * time.Second
+
+// RunAsLeader campaigns for leadership via elector and, for as long as
+// this node holds it, watches queues for reserved jobs whose TTR lease
+// has lapsed and requeues them. Every node may run it: only the node
+// currently holding leadership sweeps, so TTR expiry is handled by one
+// node at a time. It does not process delayed jobs, and it does not
+// forward commands from followers to the leader. RunAsLeader blocks until stopCh is
+// closed, re-campaigning across a lost election or a backend outage
+// rather than returning, so a transient blip never permanently strands
+// the cluster without TTR expiry.
+func RunAsLeader(elector *Elector, queues []*Queue, stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		lost, err := elector.Campaign(stopCh)
+		if err != nil {
+			select {
+			case <-time.After(campaignRetryDelay):
+			case <-stopCh:
+				return
+			}
+			continue
+		}
+
+		sweep(queues, lost, stopCh)
+	}
+}
+
+// sweep watches every queue's reserved set while this node remains
+// leader, returning as soon as leadership is lost or stopCh closes so
+// RunAsLeader can re-campaign.
+func sweep(queues []*Queue, lost <-chan struct{}, stopCh chan struct{}) {
+	watchStop := make(chan struct{})
+	go func() {
+		select {
+		case <-lost:
+		case <-stopCh:
+		}
+		close(watchStop)
+	}()
+
+	var wg sync.WaitGroup
+	for _, q := range queues {
+		wg.Add(1)
+		go func(q *Queue) {
+			defer wg.Done()
+			q.watchAndExpireReservations(watchStop)
+		}(q)
+	}
+	wg.Wait()
+}
+
+// watchAndExpireReservations keeps a live view of this tube's reserved
+// jobs via a long-lived watch on the backend, rather than diffing
+// snapshots taken on a fixed tick: a reservation whose TTR is shorter
+// than a polling interval would lapse and be requeued-to-never between
+// two ticks, since the backend had already dropped the key by the time
+// either snapshot was taken. Watching the reserved directory instead
+// means we're notified as soon as the backend removes a lapsed key, no
+// matter how short its TTR was. It returns once stopCh is closed.
+func (q *Queue) watchAndExpireReservations(stopCh <-chan struct{}) {
+	dir := q.dir(architecture.RESERVED)
+	updates, err := q.kv.WatchTree(dir, stopCh)
+	if err != nil {
+		return
+	}
+
+	known := map[string]*architecture.Job{}
+	for {
+		select {
+		case <-stopCh:
+			return
+		case pairs, ok := <-updates:
+			if !ok {
+				return
+			}
+			seen := make(map[string]*architecture.Job, len(pairs))
+			for _, p := range pairs {
+				var r record
+				if err := json.Unmarshal(p.Value, &r); err != nil {
+					continue
+				}
+				seen[r.Job.Id()] = r.Job
+			}
+			for id, j := range known {
+				if _, stillReserved := seen[id]; !stillReserved {
+					q.Requeue(j)
+				}
+			}
+			known = seen
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package stream buffers job lifecycle events published by the
+// architecture package and fans them out to any number of subscribers.
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+// ErrSubscriptionClosed is returned by Subscription.Next once the buffer
+// has pruned the item the subscription was parked on. The subscriber fell
+// too far behind and must re-subscribe.
+var ErrSubscriptionClosed = errors.New("stream: subscription closed")
+
+// Filter restricts a Subscription to events for a set of tubes and kinds.
+// A nil or empty Tubes/Kinds matches every tube/kind respectively.
+type Filter struct {
+	Tubes []string
+	Kinds []architecture.EventKind
+}
+
+func (f Filter) match(e architecture.Event) bool {
+	if len(f.Tubes) > 0 {
+		found := false
+		for _, t := range f.Tubes {
+			if t == e.Tube {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Kinds) > 0 {
+		found := false
+		for _, k := range f.Kinds {
+			if k == e.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// BufferItem is one node of the EventBuffer's append-only linked list. It
+// holds a batch of events inserted together, an atomic pointer to the next
+// item, and a link channel that is closed once next is set so parked
+// subscribers wake up immediately.
+type BufferItem struct {
+	events     []architecture.Event
+	insertedAt int64
+	evicted    int32        // set by the pruner once this item falls before head
+	next       atomic.Value // *BufferItem
+	link       chan struct{}
+}
+
+func newBufferItem() *BufferItem {
+	return &BufferItem{link: make(chan struct{})}
+}
+
+// EventBuffer is a lock-free-for-readers, append-only ring of
+// architecture.Events. Publishers append new items under a mutex;
+// subscribers read forward from wherever they last were using only atomic
+// loads and the per-item link channel, so a slow subscriber never blocks a
+// publisher.
+type EventBuffer struct {
+	publishMu sync.Mutex
+	head      atomic.Value // *BufferItem
+	tail      atomic.Value // *BufferItem
+	count     int64        // events currently buffered, for the cap check
+
+	ttl      time.Duration
+	maxItems int
+}
+
+// NewEventBuffer creates an EventBuffer that prunes items older than ttl
+// (if ttl > 0) or once more than maxItems events are buffered (if
+// maxItems > 0), and starts its background pruner.
+func NewEventBuffer(ttl time.Duration, maxItems int) *EventBuffer {
+	root := newBufferItem()
+	b := &EventBuffer{ttl: ttl, maxItems: maxItems}
+	b.head.Store(root)
+	b.tail.Store(root)
+	go b.prune()
+	return b
+}
+
+// Publish appends e to the buffer and wakes any subscriber parked on the
+// previous tail.
+func (b *EventBuffer) Publish(e architecture.Event) {
+	item := newBufferItem()
+	item.events = []architecture.Event{e}
+	item.insertedAt = time.Now().Unix()
+
+	b.publishMu.Lock()
+	oldTail := b.tail.Load().(*BufferItem)
+	oldTail.next.Store(item)
+	b.tail.Store(item)
+	atomic.AddInt64(&b.count, 1)
+	b.publishMu.Unlock()
+
+	close(oldTail.link)
+}
+
+// Subscribe returns a Subscription positioned at the current tail, i.e.
+// it only sees events published from now on.
+func (b *EventBuffer) Subscribe(filter Filter) *Subscription {
+	tail := b.tail.Load().(*BufferItem)
+	return &Subscription{
+		buf:    b,
+		filter: filter,
+		item:   tail,
+		idx:    len(tail.events),
+	}
+}
+
+func (b *EventBuffer) prune() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		b.publishMu.Lock()
+		now := time.Now().Unix()
+		head := b.head.Load().(*BufferItem)
+		for {
+			next, _ := head.next.Load().(*BufferItem)
+			if next == nil {
+				break
+			}
+			expired := b.ttl > 0 && now-head.insertedAt > int64(b.ttl/time.Second)
+			overCap := b.maxItems > 0 && atomic.LoadInt64(&b.count) > int64(b.maxItems)
+			if !expired && !overCap {
+				break
+			}
+			atomic.StoreInt32(&head.evicted, 1)
+			atomic.AddInt64(&b.count, -int64(len(head.events)))
+			head = next
+		}
+		b.head.Store(head)
+		b.publishMu.Unlock()
+	}
+}
+
+// Subscription walks an EventBuffer forward from the point it was created,
+// yielding only events matching its Filter.
+type Subscription struct {
+	buf    *EventBuffer
+	filter Filter
+	item   *BufferItem
+	idx    int
+}
+
+// Next blocks until a matching event is available, ctx is done, or the
+// subscription falls behind the buffer's pruner (ErrSubscriptionClosed).
+func (s *Subscription) Next(ctx context.Context) (architecture.Event, error) {
+	for {
+		if atomic.LoadInt32(&s.item.evicted) == 1 {
+			return architecture.Event{}, ErrSubscriptionClosed
+		}
+		if s.idx < len(s.item.events) {
+			e := s.item.events[s.idx]
+			s.idx++
+			if s.filter.match(e) {
+				return e, nil
+			}
+			continue
+		}
+		next, _ := s.item.next.Load().(*BufferItem)
+		if next == nil {
+			select {
+			case <-s.item.link:
+			case <-ctx.Done():
+				return architecture.Event{}, ctx.Err()
+			}
+			continue
+		}
+		s.item = next
+		s.idx = 0
+	}
+}
+
+// Publisher adapts an EventBuffer to architecture.Sink so it can be
+// installed as architecture.Publisher.
+type Publisher struct {
+	buf *EventBuffer
+}
+
+// NewPublisher wraps buf as an architecture.Sink.
+func NewPublisher(buf *EventBuffer) *Publisher {
+	return &Publisher{buf: buf}
+}
+
+// Publish implements architecture.Sink.
+func (p *Publisher) Publish(e architecture.Event) {
+	p.buf.Publish(e)
+}
@@ -0,0 +1,103 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Beanstalkg/beanstalkg/architecture"
+)
+
+func TestFanOutToMultipleSubscribers(t *testing.T) {
+	buf := NewEventBuffer(0, 0)
+	const n = 5
+	subs := make([]*Subscription, n)
+	for i := range subs {
+		subs[i] = buf.Subscribe(Filter{})
+	}
+
+	events := []architecture.Event{
+		{Tube: "t", Kind: architecture.EventStateChange, JobID: "1"},
+		{Tube: "t", Kind: architecture.EventStateChange, JobID: "2"},
+		{Tube: "t", Kind: architecture.EventStateChange, JobID: "3"},
+	}
+	for _, e := range events {
+		buf.Publish(e)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for i, sub := range subs {
+		for j, want := range events {
+			got, err := sub.Next(ctx)
+			if err != nil {
+				t.Fatalf("subscriber %d event %d: %v", i, j, err)
+			}
+			if got.JobID != want.JobID {
+				t.Fatalf("subscriber %d event %d: got JobID %q, want %q", i, j, got.JobID, want.JobID)
+			}
+		}
+	}
+}
+
+func TestFilterMatchesOnlySubscribedTubesAndKinds(t *testing.T) {
+	buf := NewEventBuffer(0, 0)
+	sub := buf.Subscribe(Filter{
+		Tubes: []string{"a"},
+		Kinds: []architecture.EventKind{architecture.EventDeleted},
+	})
+
+	buf.Publish(architecture.Event{Tube: "b", Kind: architecture.EventDeleted, JobID: "wrong-tube"})
+	buf.Publish(architecture.Event{Tube: "a", Kind: architecture.EventStateChange, JobID: "wrong-kind"})
+	buf.Publish(architecture.Event{Tube: "a", Kind: architecture.EventDeleted, JobID: "match"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	got, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.JobID != "match" {
+		t.Fatalf("got JobID %q, want %q", got.JobID, "match")
+	}
+}
+
+// TestPruningUnderTTL checks that a subscription parked before an event
+// ages out gets ErrSubscriptionClosed once the background pruner catches
+// up, rather than being able to read the stale event forever.
+func TestPruningUnderTTL(t *testing.T) {
+	buf := NewEventBuffer(50*time.Millisecond, 0)
+	sub := buf.Subscribe(Filter{}) // positioned before the event below exists
+
+	buf.Publish(architecture.Event{Tube: "t", JobID: "ages-out"})
+
+	// The pruner ticks once a second; give it time to run well past the
+	// 50ms TTL.
+	time.Sleep(1200 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := sub.Next(ctx); err != ErrSubscriptionClosed {
+		t.Fatalf("got err %v, want ErrSubscriptionClosed", err)
+	}
+}
+
+// TestSlowConsumerEviction checks that a subscriber who never reads gets
+// ErrSubscriptionClosed once the buffer's item cap forces the pruner to
+// evict the item it's parked on, instead of pinning that memory forever.
+func TestSlowConsumerEviction(t *testing.T) {
+	buf := NewEventBuffer(0, 1) // cap of 1 buffered event
+	sub := buf.Subscribe(Filter{})
+
+	for i := 0; i < 3; i++ {
+		buf.Publish(architecture.Event{Tube: "t", JobID: "flood"})
+	}
+
+	time.Sleep(1200 * time.Millisecond) // let the pruner catch up to the cap
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := sub.Next(ctx); err != ErrSubscriptionClosed {
+		t.Fatalf("got err %v, want ErrSubscriptionClosed", err)
+	}
+}